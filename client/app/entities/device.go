@@ -0,0 +1,22 @@
+package entities
+
+// DeviceSpecs holds the basic machine information reported to the server.
+type DeviceSpecs struct {
+	Hostname     string `json:"hostname"`
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+}
+
+// Directory is the result of listing a filesystem path for the explore command.
+type Directory struct {
+	Path  string `json:"path"`
+	Files []File `json:"files"`
+}
+
+// File describes a single entry returned by the explore command.
+type File struct {
+	Name    string `json:"name"`
+	Size    int64  `json:"size"`
+	IsDir   bool   `json:"isDir"`
+	ModTime string `json:"modTime"`
+}
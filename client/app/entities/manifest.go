@@ -0,0 +1,18 @@
+package entities
+
+import "time"
+
+// ManifestEntry describes one file inside a download-zip archive. The
+// manifest is written as the archive's last entry (see archive.StreamZip for
+// why it trails rather than leads), so the server verifies every file's
+// sha256 after reading the whole archive rather than streaming verification
+// as entries arrive. Path is the zip entry name: the base name of the root it
+// was collected under followed by its path below that root, not the original
+// (often absolute) filesystem path.
+type ManifestEntry struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	Mode    uint32    `json:"mode"`
+	ModTime time.Time `json:"modTime"`
+	SHA256  string    `json:"sha256"`
+}
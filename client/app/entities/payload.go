@@ -0,0 +1,12 @@
+package entities
+
+// Payload is the message exchanged between client and server for a single command.
+type Payload struct {
+	MacAddress string `json:"macAddress"`
+	Request    string `json:"request,omitempty"`
+	Response   []byte `json:"response,omitempty"`
+	HasError   bool   `json:"hasError"`
+	// CommandID identifies a dispatched command so the operator can cancel it
+	// later with "cancel <CommandID>".
+	CommandID string `json:"commandId,omitempty"`
+}
@@ -0,0 +1,13 @@
+package entities
+
+import "time"
+
+// Progress reports how a chunked file transfer is advancing, so the operator
+// UI can render bytes transferred and an ETA while it's still in flight.
+type Progress struct {
+	MacAddress string        `json:"macAddress"`
+	Path       string        `json:"path"`
+	BytesDone  int64         `json:"bytesDone"`
+	BytesTotal int64         `json:"bytesTotal"`
+	ETA        time.Duration `json:"eta"`
+}
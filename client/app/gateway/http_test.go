@@ -0,0 +1,74 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPGatewayStreamDeliversPayloadsUntilClosed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher := w.(http.Flusher)
+		w.WriteHeader(http.StatusOK)
+		for _, line := range []string{
+			`{"macAddress":"AA","request":"getos"}` + "\n",
+			`{"macAddress":"AA","request":"screenshot"}` + "\n",
+		} {
+			w.Write([]byte(line))
+			flusher.Flush()
+		}
+	}))
+	defer server.Close()
+
+	gw := NewHTTPGateway(server.Client())
+	payloads, err := gw.Stream(server.URL)
+	if err != nil {
+		t.Fatalf("Stream returned an error: %v", err)
+	}
+
+	var got []string
+	for p := range payloads {
+		got = append(got, p.Request)
+	}
+
+	if len(got) != 2 || got[0] != "getos" || got[1] != "screenshot" {
+		t.Fatalf("unexpected payloads: %v", got)
+	}
+}
+
+func TestHTTPGatewayStreamReturnsErrStreamUnsupportedOn426(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUpgradeRequired)
+	}))
+	defer server.Close()
+
+	gw := NewHTTPGateway(server.Client())
+	if _, err := gw.Stream(server.URL); err == nil {
+		t.Fatal("expected an error for a 426 response")
+	} else if _, ok := err.(*ErrStreamUnsupported); !ok {
+		t.Fatalf("expected *ErrStreamUnsupported, got %T: %v", err, err)
+	}
+}
+
+func TestHTTPGatewayNewRequestWithHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Content-Range"); got != "0-3/4" {
+			t.Errorf("Content-Range header not forwarded, got %q", got)
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ack"))
+	}))
+	defer server.Close()
+
+	gw := NewHTTPGateway(&http.Client{Timeout: 5 * time.Second})
+	res, err := gw.NewRequestWithHeaders(http.MethodPatch, server.URL, []byte("data"), map[string]string{
+		"Content-Range": "0-3/4",
+	})
+	if err != nil {
+		t.Fatalf("NewRequestWithHeaders returned an error: %v", err)
+	}
+	if res.StatusCode != http.StatusOK || string(res.ResponseBody) != "ack" {
+		t.Fatalf("unexpected response: %+v", res)
+	}
+}
@@ -0,0 +1,26 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+)
+
+// Cancel stops an in-flight command by the UUID it was dispatched with.
+type Cancel struct{}
+
+func (Cancel) Name() string { return "cancel" }
+
+func (Cancel) Parse(tokens []string) (Args, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("cancel requires a command id")
+	}
+	return Args{"id": tokens[0]}, nil
+}
+
+func (Cancel) Run(ctx context.Context, args Args, deps *Deps) (Response, error) {
+	id := args["id"]
+	if !deps.Cancel(id) {
+		return Response{}, fmt.Errorf("no running command with id %s", id)
+	}
+	return Response{Body: []byte(fmt.Sprint("cancelled ", id))}, nil
+}
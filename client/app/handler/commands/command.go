@@ -0,0 +1,41 @@
+// Package commands splits the command dispatcher into one type per command,
+// replacing a single switch that mixed parsing, dispatch and response
+// encoding together.
+package commands
+
+import (
+	"context"
+
+	"github.com/tiagorlampert/CHAOS/client/app/entities"
+	"github.com/tiagorlampert/CHAOS/client/app/services"
+)
+
+// Args holds a command's parsed arguments, keyed by name.
+type Args map[string]string
+
+// Response is what a Command hands back to be sent to the server. A failed
+// command returns a non-nil error instead of setting a field here.
+type Response struct {
+	Body []byte
+}
+
+// Deps bundles everything a Command's Run needs beyond its own arguments.
+type Deps struct {
+	Services   *services.Services
+	MacAddress string
+	OnProgress func(entities.Progress)
+	// Cancel stops the in-flight command identified by commandID, reporting
+	// whether one was found running.
+	Cancel func(commandID string) bool
+}
+
+// Command is a single dispatchable command: getos, screenshot, download, etc.
+type Command interface {
+	// Name is the lowercase keyword that selects this command, e.g. "getos".
+	Name() string
+	// Parse turns the tokens following the command name (already split by a
+	// shell-style tokenizer that respects quoted paths) into Args.
+	Parse(tokens []string) (Args, error)
+	// Run executes the command and returns its response.
+	Run(ctx context.Context, args Args, deps *Deps) (Response, error)
+}
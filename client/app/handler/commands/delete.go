@@ -0,0 +1,22 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type Delete struct{}
+
+func (Delete) Name() string { return "delete" }
+
+func (Delete) Parse(tokens []string) (Args, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("delete requires a path")
+	}
+	return Args{"path": strings.Join(tokens, " ")}, nil
+}
+
+func (Delete) Run(ctx context.Context, args Args, deps *Deps) (Response, error) {
+	return Response{}, deps.Services.Delete.DeleteFile(ctx, args["path"])
+}
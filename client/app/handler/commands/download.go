@@ -0,0 +1,29 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Download is the "download" command: it reads a file off this machine and
+// streams it up to the server, using the resumable chunked Upload protocol
+// despite the name (the command is named from the operator's point of view).
+type Download struct{}
+
+func (Download) Name() string { return "download" }
+
+func (Download) Parse(tokens []string) (Args, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("download requires a path")
+	}
+	return Args{"path": strings.Join(tokens, " ")}, nil
+}
+
+func (Download) Run(ctx context.Context, args Args, deps *Deps) (Response, error) {
+	body, err := deps.Services.Upload.UploadFileChunked(ctx, args["path"], deps.MacAddress, deps.OnProgress)
+	if err != nil {
+		return Response{}, err
+	}
+	return Response{Body: body}, nil
+}
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// pathSeparator joins multiple paths into a single Args value; a null byte
+// can't appear in a filesystem path, so it's a safe delimiter.
+const pathSeparator = "\x00"
+
+// DownloadZip streams one or more directories to the server as a single zip,
+// built on the fly over the same chunked upload session Upload uses, so a
+// large directory can be pulled back in one command instead of file by
+// file. Unlike a plain file Upload, this streamed transfer can't resume a
+// dropped connection (see blob.Uploader.UploadStream) - a failure returns an
+// error, and running the command again rebuilds and sends the whole zip from
+// scratch.
+type DownloadZip struct{}
+
+func (DownloadZip) Name() string { return "download-zip" }
+
+func (DownloadZip) Parse(tokens []string) (Args, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("download-zip requires at least one path")
+	}
+	return Args{"paths": strings.Join(tokens, pathSeparator)}, nil
+}
+
+func (DownloadZip) Run(ctx context.Context, args Args, deps *Deps) (Response, error) {
+	paths := strings.Split(args["paths"], pathSeparator)
+
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		pipeWriter.CloseWithError(deps.Services.Archive.StreamZip(ctx, paths, pipeWriter))
+	}()
+	// If UploadStreamChunked returns early (ctx cancelled, upload error), the
+	// archive goroutine above is still blocked writing to pipeWriter. Closing
+	// our end unblocks its io.Copy with io.ErrClosedPipe so it can return and
+	// release the file it has open, instead of leaking the goroutine forever.
+	defer pipeReader.Close()
+
+	body, err := deps.Services.Upload.UploadStreamChunked(ctx, pipeReader, strings.Join(paths, ","), deps.MacAddress, deps.OnProgress)
+	if err != nil {
+		return Response{}, err
+	}
+	return Response{Body: body}, nil
+}
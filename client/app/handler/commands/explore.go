@@ -0,0 +1,31 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type Explore struct{}
+
+func (Explore) Name() string { return "explore" }
+
+func (Explore) Parse(tokens []string) (Args, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("explore requires a path")
+	}
+	return Args{"path": strings.Join(tokens, " ")}, nil
+}
+
+func (Explore) Run(ctx context.Context, args Args, deps *Deps) (Response, error) {
+	directory, err := deps.Services.Explorer.ExploreDirectory(ctx, args["path"])
+	if err != nil {
+		return Response{}, err
+	}
+	body, err := json.Marshal(directory)
+	if err != nil {
+		return Response{}, err
+	}
+	return Response{Body: body}, nil
+}
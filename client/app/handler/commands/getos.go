@@ -0,0 +1,21 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/tiagorlampert/CHAOS/client/app/utilities/encode"
+)
+
+type GetOS struct{}
+
+func (GetOS) Name() string { return "getos" }
+
+func (GetOS) Parse(tokens []string) (Args, error) { return nil, nil }
+
+func (GetOS) Run(ctx context.Context, args Args, deps *Deps) (Response, error) {
+	deviceSpecs, err := deps.Services.Information.LoadDeviceSpecs(ctx)
+	if err != nil {
+		return Response{}, err
+	}
+	return Response{Body: encode.StringToByte(encode.PrettyJson(deviceSpecs))}, nil
+}
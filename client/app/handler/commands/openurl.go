@@ -0,0 +1,21 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+)
+
+type OpenURL struct{}
+
+func (OpenURL) Name() string { return "open-url" }
+
+func (OpenURL) Parse(tokens []string) (Args, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("open-url requires a url")
+	}
+	return Args{"url": tokens[0]}, nil
+}
+
+func (OpenURL) Run(ctx context.Context, args Args, deps *Deps) (Response, error) {
+	return Response{}, deps.Services.URL.OpenURL(ctx, args["url"])
+}
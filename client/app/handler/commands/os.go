@@ -0,0 +1,46 @@
+package commands
+
+import "context"
+
+// Restart, Shutdown, Lock and SignOut are thin wrappers around the matching
+// OSService method; none of them take arguments or return a response body.
+
+type Restart struct{}
+
+func (Restart) Name() string { return "restart" }
+
+func (Restart) Parse(tokens []string) (Args, error) { return nil, nil }
+
+func (Restart) Run(ctx context.Context, args Args, deps *Deps) (Response, error) {
+	return Response{}, deps.Services.OS.Restart(ctx)
+}
+
+type Shutdown struct{}
+
+func (Shutdown) Name() string { return "shutdown" }
+
+func (Shutdown) Parse(tokens []string) (Args, error) { return nil, nil }
+
+func (Shutdown) Run(ctx context.Context, args Args, deps *Deps) (Response, error) {
+	return Response{}, deps.Services.OS.Shutdown(ctx)
+}
+
+type Lock struct{}
+
+func (Lock) Name() string { return "lock" }
+
+func (Lock) Parse(tokens []string) (Args, error) { return nil, nil }
+
+func (Lock) Run(ctx context.Context, args Args, deps *Deps) (Response, error) {
+	return Response{}, deps.Services.OS.Lock(ctx)
+}
+
+type SignOut struct{}
+
+func (SignOut) Name() string { return "sign-out" }
+
+func (SignOut) Parse(tokens []string) (Args, error) { return nil, nil }
+
+func (SignOut) Run(ctx context.Context, args Args, deps *Deps) (Response, error) {
+	return Response{}, deps.Services.OS.SignOut(ctx)
+}
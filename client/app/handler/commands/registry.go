@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"strings"
+	"sync"
+)
+
+// Registry looks up a Command by name. Registering the built-in commands
+// happens once, from handler.NewHandler; third parties can add their own
+// Commands the same way behind a build tag.
+type Registry struct {
+	mu       sync.RWMutex
+	commands map[string]Command
+}
+
+func NewRegistry() *Registry {
+	return &Registry{commands: make(map[string]Command)}
+}
+
+// Register adds cmd, keyed by its lowercased Name(). Registering a command
+// under a name that's already taken replaces the previous one.
+func (r *Registry) Register(cmd Command) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commands[strings.ToLower(cmd.Name())] = cmd
+}
+
+// Lookup returns the command registered under name, case-insensitively.
+func (r *Registry) Lookup(name string) (Command, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cmd, ok := r.commands[strings.ToLower(name)]
+	return cmd, ok
+}
@@ -0,0 +1,17 @@
+package commands
+
+import "context"
+
+type Screenshot struct{}
+
+func (Screenshot) Name() string { return "screenshot" }
+
+func (Screenshot) Parse(tokens []string) (Args, error) { return nil, nil }
+
+func (Screenshot) Run(ctx context.Context, args Args, deps *Deps) (Response, error) {
+	image, err := deps.Services.Screenshot.TakeScreenshot(ctx)
+	if err != nil {
+		return Response{}, err
+	}
+	return Response{Body: image}, nil
+}
@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Tokenize splits a raw command line into words, the way a shell would:
+// whitespace separates tokens, and single or double quotes let a token
+// (typically a file path) contain spaces. This replaces the previous
+// strings.Split(raw, " "), which broke on any quoted or spaced path.
+func Tokenize(raw string) ([]string, error) {
+	var tokens []string
+	var current strings.Builder
+	var quote rune
+	inQuotes := false
+
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, r := range raw {
+		switch {
+		case inQuotes:
+			if r == quote {
+				inQuotes = false
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			inQuotes = true
+			quote = r
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated %q quote in command", string(quote))
+	}
+	flush()
+
+	return tokens, nil
+}
@@ -0,0 +1,29 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Upload is the "upload" command: it fetches a file from the server down to
+// this machine, using the resumable chunked Download protocol.
+type Upload struct{}
+
+func (Upload) Name() string { return "upload" }
+
+func (Upload) Parse(tokens []string) (Args, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("upload requires a path")
+	}
+	return Args{"path": strings.Join(tokens, " ")}, nil
+}
+
+func (Upload) Run(ctx context.Context, args Args, deps *Deps) (Response, error) {
+	path := args["path"]
+	body, err := deps.Services.Download.DownloadFileChunked(ctx, path, path, deps.MacAddress, deps.OnProgress)
+	if err != nil {
+		return Response{}, err
+	}
+	return Response{Body: body}, nil
+}
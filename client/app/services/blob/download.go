@@ -0,0 +1,152 @@
+package blob
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tiagorlampert/CHAOS/client/app/gateway"
+)
+
+// Downloader pulls a file from the server in chunks using Range requests,
+// persisting the last written offset so a dropped connection or process
+// restart resumes instead of starting over.
+type Downloader struct {
+	Gateway   gateway.Gateway
+	ChunkSize int
+}
+
+func NewDownloader(gw gateway.Gateway) *Downloader {
+	return &Downloader{Gateway: gw, ChunkSize: DefaultChunkSize}
+}
+
+// Download fetches remoteURL into localPath, resuming from any offset
+// persisted for macAddress+remoteURL by a previous, interrupted attempt. It
+// aborts with ctx.Err() as soon as ctx is cancelled or its deadline passes;
+// the offset already written to disk is preserved for the next attempt.
+func (d *Downloader) Download(ctx context.Context, remoteURL, localPath, macAddress string, onProgress ProgressFunc) ([]byte, error) {
+	offsetPath := offsetFilePath(macAddress, remoteURL)
+	offset := readOffset(offsetPath)
+
+	file, err := os.OpenFile(localPath, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	chunkSize := d.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	start := time.Now()
+	var total int64 = -1
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		requestOffset := offset
+		res, err := d.Gateway.NewRequestWithHeaders(http.MethodGet, remoteURL, nil, map[string]string{
+			"Range": fmt.Sprintf("bytes=%d-%d", offset, offset+int64(chunkSize)-1),
+		})
+		if err != nil {
+			// offsetPath still reflects the last byte written to disk, so the
+			// next Download call resumes here.
+			return nil, err
+		}
+
+		switch res.StatusCode {
+		case http.StatusPartialContent:
+			// Server honored the Range header; res.ResponseBody picks up at
+			// requestOffset as asked.
+		case http.StatusOK:
+			if requestOffset > 0 {
+				// Server ignored our Range header and sent the whole file
+				// from byte 0 instead of resuming; discard what we'd already
+				// written rather than append the full body at requestOffset.
+				if err := file.Truncate(0); err != nil {
+					return nil, err
+				}
+				if _, err := file.Seek(0, io.SeekStart); err != nil {
+					return nil, err
+				}
+				offset = 0
+				total = -1
+			}
+		default:
+			return nil, fmt.Errorf("unexpected status code %d fetching %s", res.StatusCode, remoteURL)
+		}
+
+		if total < 0 {
+			total = contentRangeTotal(res, int64(len(res.ResponseBody))+offset)
+		}
+		if len(res.ResponseBody) == 0 {
+			break
+		}
+		if _, err := file.Write(res.ResponseBody); err != nil {
+			return nil, err
+		}
+		offset += int64(len(res.ResponseBody))
+		writeOffset(offsetPath, offset)
+		if onProgress != nil {
+			onProgress(progressFor(macAddress, remoteURL, offset, total, start))
+		}
+		if res.StatusCode == http.StatusOK || int64(len(res.ResponseBody)) < int64(chunkSize) {
+			break
+		}
+	}
+
+	os.Remove(offsetPath)
+	return []byte(fmt.Sprintf("downloaded %d bytes to %s", offset, localPath)), nil
+}
+
+func contentRangeTotal(res *gateway.Response, fallback int64) int64 {
+	contentRange := res.Header.Get("Content-Range")
+	if contentRange == "" {
+		return fallback
+	}
+	idx := strings.LastIndexByte(contentRange, '/')
+	if idx < 0 || idx == len(contentRange)-1 {
+		return fallback
+	}
+	total, err := strconv.ParseInt(contentRange[idx+1:], 10, 64)
+	if err != nil {
+		return fallback
+	}
+	return total
+}
+
+// offsetFilePath keys the persisted resume offset by MAC address and remote
+// path so multiple in-flight transfers don't collide.
+func offsetFilePath(macAddress, remoteURL string) string {
+	sum := sha256.Sum256([]byte(macAddress + remoteURL))
+	return filepath.Join(os.TempDir(), "chaos-resume-"+hex.EncodeToString(sum[:])+".offset")
+}
+
+func readOffset(path string) int64 {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	offset, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return offset
+}
+
+func writeOffset(path string, offset int64) {
+	_ = os.WriteFile(path, []byte(strconv.FormatInt(offset, 10)), 0644)
+}
@@ -0,0 +1,156 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tiagorlampert/CHAOS/client/app/entities"
+	"github.com/tiagorlampert/CHAOS/client/app/gateway"
+)
+
+// downloadFakeGateway serves data over Range-aware GETs, with the ability to
+// fail one GET call (simulating a dropped connection) or force a later GET
+// to ignore the Range header and return the whole body as a server that
+// doesn't support resume would.
+type downloadFakeGateway struct {
+	data           []byte
+	gets           int
+	failAtGet      int // 1-indexed GET call to fail; 0 never fails
+	forceFullOnGet int // 1-indexed GET call to answer with a full 200; 0 never
+}
+
+func (g *downloadFakeGateway) NewRequest(method, url string, body []byte) (*gateway.Response, error) {
+	return nil, fmt.Errorf("unexpected request %s %s", method, url)
+}
+
+func (g *downloadFakeGateway) NewRequestWithHeaders(method, url string, body []byte, headers map[string]string) (*gateway.Response, error) {
+	if method != http.MethodGet {
+		return nil, fmt.Errorf("unexpected method %s", method)
+	}
+	g.gets++
+	if g.failAtGet != 0 && g.gets == g.failAtGet {
+		return nil, fmt.Errorf("connection reset by peer")
+	}
+
+	if g.forceFullOnGet != 0 && g.gets == g.forceFullOnGet {
+		return &gateway.Response{
+			StatusCode:   http.StatusOK,
+			ResponseBody: append([]byte(nil), g.data...),
+			Header:       http.Header{"Content-Range": []string{fmt.Sprintf("bytes 0-%d/%d", len(g.data)-1, len(g.data))}},
+		}, nil
+	}
+
+	start, end, err := parseRangeHeader(headers["Range"])
+	if err != nil {
+		return nil, err
+	}
+	if start >= int64(len(g.data)) {
+		return &gateway.Response{StatusCode: http.StatusPartialContent}, nil
+	}
+	if end >= int64(len(g.data)) {
+		end = int64(len(g.data)) - 1
+	}
+	return &gateway.Response{
+		StatusCode:   http.StatusPartialContent,
+		ResponseBody: g.data[start : end+1],
+		Header:       http.Header{"Content-Range": []string{fmt.Sprintf("bytes %d-%d/%d", start, end, len(g.data))}},
+	}, nil
+}
+
+func (g *downloadFakeGateway) Stream(url string) (<-chan entities.Payload, error) {
+	return nil, fmt.Errorf("stream not supported by fake gateway")
+}
+
+func parseRangeHeader(h string) (start, end int64, err error) {
+	h = strings.TrimPrefix(h, "bytes=")
+	dash := strings.IndexByte(h, '-')
+	if dash < 0 {
+		return 0, 0, fmt.Errorf("malformed Range %q", h)
+	}
+	if start, err = strconv.ParseInt(h[:dash], 10, 64); err != nil {
+		return 0, 0, err
+	}
+	if end, err = strconv.ParseInt(h[dash+1:], 10, 64); err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// TestDownloadResumesAfterConnectionDrop kills the simulated connection
+// partway through a download and verifies a second Download call resumes
+// from the persisted offset instead of starting over.
+func TestDownloadResumesAfterConnectionDrop(t *testing.T) {
+	dir := t.TempDir()
+	localPath := filepath.Join(dir, "out.bin")
+	data := bytes.Repeat([]byte("b"), 3*16+7)
+	macAddress := "11:22:33:44:55:66"
+	remoteURL := "http://fake.test/files/out.bin"
+
+	fake := &downloadFakeGateway{data: data, failAtGet: 2}
+	downloader := &Downloader{Gateway: fake, ChunkSize: 16}
+
+	if _, err := downloader.Download(context.Background(), remoteURL, localPath, macAddress, nil); err == nil {
+		t.Fatal("expected the simulated connection drop to surface as an error")
+	}
+
+	offsetPath := offsetFilePath(macAddress, remoteURL)
+	if readOffset(offsetPath) == 0 {
+		t.Fatal("expected a persisted offset after the dropped chunk")
+	}
+
+	if _, err := downloader.Download(context.Background(), remoteURL, localPath, macAddress, nil); err != nil {
+		t.Fatalf("resumed download failed: %v", err)
+	}
+
+	got, err := os.ReadFile(localPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatal("resumed download produced different bytes than the source")
+	}
+	if _, err := os.Stat(offsetPath); !os.IsNotExist(err) {
+		t.Fatal("expected the offset file to be removed after a successful download")
+	}
+}
+
+// TestDownloadRestartsWhenServerIgnoresRangeOnResume covers the corruption
+// the Range/Content-Range handling used to have: resuming from a persisted
+// offset against a server that answers 200 (ignoring Range) instead of 206
+// must restart from byte zero, not append the full body at the old offset.
+func TestDownloadRestartsWhenServerIgnoresRangeOnResume(t *testing.T) {
+	dir := t.TempDir()
+	localPath := filepath.Join(dir, "out.bin")
+	data := bytes.Repeat([]byte("c"), 40)
+	macAddress := "AA:AA:AA:AA:AA:AA"
+	remoteURL := "http://fake.test/files/out2.bin"
+
+	// Pretend a previous, interrupted attempt already wrote the first 20
+	// bytes and persisted that offset.
+	if err := os.WriteFile(localPath, bytes.Repeat([]byte("X"), 20), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeOffset(offsetFilePath(macAddress, remoteURL), 20)
+
+	fake := &downloadFakeGateway{data: data, forceFullOnGet: 1}
+	downloader := &Downloader{Gateway: fake, ChunkSize: 16}
+
+	if _, err := downloader.Download(context.Background(), remoteURL, localPath, macAddress, nil); err != nil {
+		t.Fatalf("download failed: %v", err)
+	}
+
+	got, err := os.ReadFile(localPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("expected the restarted download to match the source exactly, got %q", got)
+	}
+}
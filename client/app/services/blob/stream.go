@@ -0,0 +1,88 @@
+package blob
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// UploadStream is Upload for content whose total size isn't known up front
+// (a zip archive being streamed off disk as it's built, for example): chunks
+// carry an open-ended Content-Range total ("*") until the final, short read
+// closes it out. A stream whose length is an exact multiple of ChunkSize has
+// no short read, so all of its chunks carry "*" and no total is sent.
+//
+// Unlike Upload, UploadStream is not resumable: Upload can reopen path and
+// re-read from a persisted offset after a dropped connection because a
+// plain file supports that, but r here is whatever the caller is piping
+// through (e.g. a zip being built on the fly) and can't be rewound to any
+// offset that isn't 0. A dropped connection mid-stream loses whatever r had
+// already produced, so the caller has to regenerate r and start over rather
+// than resume into the old session.
+func (u *Uploader) UploadStream(ctx context.Context, r io.Reader, path, macAddress string, onProgress ProgressFunc) ([]byte, error) {
+	location, err := u.openSession()
+	if err != nil {
+		return nil, fmt.Errorf("opening blob session: %w", err)
+	}
+
+	chunkSize := u.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	hash := sha256.New()
+	buf := make([]byte, chunkSize)
+	var offset int64
+	start := time.Now()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		n, readErr := io.ReadFull(r, buf)
+		done := readErr == io.EOF || readErr == io.ErrUnexpectedEOF
+		if readErr != nil && !done {
+			return nil, readErr
+		}
+
+		if n > 0 {
+			chunk := buf[:n]
+			hash.Write(chunk)
+
+			total := "*"
+			if done {
+				total = strconv.FormatInt(offset+int64(n), 10)
+			}
+			res, err := u.Gateway.NewRequestWithHeaders(http.MethodPatch, location, chunk, map[string]string{
+				"Content-Range": fmt.Sprintf("%d-%d/%s", offset, offset+int64(n)-1, total),
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			offset = nextOffset(res, offset+int64(n))
+			if onProgress != nil {
+				// The total is unknown until the stream ends, so report
+				// BytesTotal as whatever's been sent so far.
+				onProgress(progressFor(macAddress, path, offset, offset, start))
+			}
+		}
+
+		if done {
+			break
+		}
+	}
+
+	digest := "sha256:" + hex.EncodeToString(hash.Sum(nil))
+	res, err := u.Gateway.NewRequest(http.MethodPut, fmt.Sprintf("%s?digest=%s", location, digest), nil)
+	if err != nil {
+		return nil, err
+	}
+	return res.ResponseBody, nil
+}
@@ -0,0 +1,138 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tiagorlampert/CHAOS/client/app/entities"
+	"github.com/tiagorlampert/CHAOS/client/app/gateway"
+)
+
+// uploadFakeGateway is a minimal gateway.Gateway that plays blob's session
+// protocol (POST to open, PATCH chunks, PUT to close) against an in-memory
+// buffer, with the ability to fail one PATCH call to simulate a dropped
+// connection mid-transfer.
+type uploadFakeGateway struct {
+	location    string
+	data        []byte
+	patches     int
+	failAtPatch int // 1-indexed PATCH call to fail; 0 never fails
+	digestOK    bool
+}
+
+func (g *uploadFakeGateway) NewRequest(method, url string, body []byte) (*gateway.Response, error) {
+	switch method {
+	case http.MethodPost:
+		g.location = "http://fake.test/blob/session-1"
+		return &gateway.Response{
+			StatusCode: http.StatusCreated,
+			Header:     http.Header{"Location": []string{g.location}},
+		}, nil
+	case http.MethodPut:
+		idx := strings.Index(url, "digest=")
+		digest := url[idx+len("digest="):]
+		sum := sha256.Sum256(g.data)
+		g.digestOK = digest == "sha256:"+hex.EncodeToString(sum[:])
+		return &gateway.Response{StatusCode: http.StatusOK, ResponseBody: []byte("ok")}, nil
+	}
+	return nil, fmt.Errorf("unexpected request %s %s", method, url)
+}
+
+func (g *uploadFakeGateway) NewRequestWithHeaders(method, url string, body []byte, headers map[string]string) (*gateway.Response, error) {
+	if method != http.MethodPatch {
+		return nil, fmt.Errorf("unexpected method %s", method)
+	}
+	g.patches++
+	if g.failAtPatch != 0 && g.patches == g.failAtPatch {
+		return nil, fmt.Errorf("connection reset by peer")
+	}
+
+	start, end, err := parseContentRange(headers["Content-Range"])
+	if err != nil {
+		return nil, err
+	}
+	if need := end + 1; int64(len(g.data)) < need {
+		grown := make([]byte, need)
+		copy(grown, g.data)
+		g.data = grown
+	}
+	copy(g.data[start:end+1], body)
+
+	return &gateway.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Range": []string{fmt.Sprintf("bytes=%d-%d", start, end)}},
+	}, nil
+}
+
+func (g *uploadFakeGateway) Stream(url string) (<-chan entities.Payload, error) {
+	return nil, fmt.Errorf("stream not supported by fake gateway")
+}
+
+func parseContentRange(h string) (start, end int64, err error) {
+	dash := strings.IndexByte(h, '-')
+	slash := strings.IndexByte(h, '/')
+	if dash < 0 || slash < 0 {
+		return 0, 0, fmt.Errorf("malformed Content-Range %q", h)
+	}
+	if start, err = strconv.ParseInt(h[:dash], 10, 64); err != nil {
+		return 0, 0, err
+	}
+	if end, err = strconv.ParseInt(h[dash+1:slash], 10, 64); err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// TestUploadResumesAfterConnectionDrop kills the simulated connection
+// partway through an upload and verifies a second Upload call resumes into
+// the same session instead of re-opening one and re-sending bytes the
+// server already acknowledged.
+func TestUploadResumesAfterConnectionDrop(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "payload.bin")
+	content := bytes.Repeat([]byte("a"), 3*16+10) // spans chunks of 16 unevenly
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	macAddress := "AA:BB:CC:DD:EE:FF"
+
+	fake := &uploadFakeGateway{failAtPatch: 2}
+	uploader := &Uploader{Gateway: fake, BlobURL: "http://fake.test/blob", ChunkSize: 16}
+
+	if _, err := uploader.Upload(context.Background(), path, macAddress, nil); err == nil {
+		t.Fatal("expected the simulated connection drop to surface as an error")
+	}
+
+	sessionPath := sessionFilePath(macAddress, path)
+	if _, err := os.Stat(sessionPath); err != nil {
+		t.Fatalf("expected a persisted session after the dropped chunk, got: %v", err)
+	}
+
+	if _, err := uploader.Upload(context.Background(), path, macAddress, nil); err != nil {
+		t.Fatalf("resumed upload failed: %v", err)
+	}
+
+	if !bytes.Equal(fake.data, content) {
+		t.Fatal("server ended up with different bytes than the source file")
+	}
+	if !fake.digestOK {
+		t.Fatal("server received a digest that didn't match the uploaded bytes")
+	}
+	if _, err := os.Stat(sessionPath); !os.IsNotExist(err) {
+		t.Fatal("expected the session file to be removed after a successful upload")
+	}
+
+	expectedChunks := (len(content) + 15) / 16
+	if fake.patches != expectedChunks+1 { // +1 for the dropped attempt
+		t.Fatalf("resume re-sent already-acknowledged bytes: got %d PATCH calls, want %d", fake.patches, expectedChunks+1)
+	}
+}
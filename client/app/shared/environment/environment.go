@@ -0,0 +1,35 @@
+package environment
+
+import "time"
+
+type Configuration struct {
+	Server     Server
+	Connection Connection
+}
+
+type Server struct {
+	URL     string
+	Command string
+	Health  string
+	Device  string
+	// Events is the endpoint used to open a persistent event stream, mirroring
+	// Command but held open by the server instead of polled.
+	Events string
+	// Transport selects how HandleCommand receives commands: "poll" (default)
+	// or "stream". Stream falls back to poll if the server doesn't support it.
+	Transport string
+	// Blob is the endpoint used to open, patch and close chunked file
+	// transfer sessions for upload/download.
+	Blob string
+	// Progress is the endpoint chunked transfers report bytes-done/ETA to.
+	Progress string
+}
+
+const (
+	TransportPoll   = "poll"
+	TransportStream = "stream"
+)
+
+type Connection struct {
+	ContextDeadline time.Duration
+}
@@ -0,0 +1,25 @@
+package encode
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// StringToByte is a small helper so callers don't sprinkle []byte(s) everywhere.
+func StringToByte(s string) []byte {
+	return []byte(s)
+}
+
+// PrettyJson marshals v into indented JSON, returning an empty string on failure.
+func PrettyJson(v interface{}) string {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// Base64Encode encodes s using standard base64.
+func Base64Encode(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
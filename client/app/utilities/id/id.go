@@ -0,0 +1,18 @@
+// Package id generates command identifiers without pulling in an external
+// UUID dependency.
+package id
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// New returns a random UUIDv4-formatted string, unique enough to correlate a
+// dispatched command with its eventual response or a later cancel request.
+func New() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}